@@ -0,0 +1,185 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// runBuildCmd implements the `morph-blog build` subcommand: it renders
+// every embedded post through the same template pipeline the server uses
+// and writes a static site to disk.
+func runBuildCmd(args []string) error {
+	fset := flag.NewFlagSet("build", flag.ExitOnError)
+	outDir := fset.String("o", "public", "output directory for the static site")
+	styleFlag := fset.String("highlight-style", chromaStyleName, "Chroma style used for syntax highlighting")
+	domainFlag := fset.String("domain", envOrDefault("MORPH_DOMAIN", feedDomain), "domain the blog is served from (also MORPH_DOMAIN)")
+	startDateFlag := fset.String("start-date", envOrDefault("MORPH_START_DATE", feedStartDate), "date (YYYY-MM-DD) the domain was first owned, anchoring feed tag: URIs (also MORPH_START_DATE)")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	chromaStyleName = *styleFlag
+	feedDomain = *domainFlag
+	feedStartDate = *startDateFlag
+	if err := validateFeedStartDate(feedStartDate); err != nil {
+		return err
+	}
+
+	return buildSite(*outDir)
+}
+
+// buildSite renders the embedded posts, listing, feeds, and sitemap into
+// outDir, reusing mdToHTML and templateCache so the static output is
+// byte-identical to what the server would have returned.
+func buildSite(outDir string) error {
+	return buildSiteFromFS(embeddedFiles, outDir)
+}
+
+// buildSiteFromFS is buildSite with the content filesystem injected, so
+// tests can exercise it against a synthetic post set.
+func buildSiteFromFS(fsys fs.FS, outDir string) error {
+	allPosts, err := loadPosts(fsys)
+	if err != nil {
+		return fmt.Errorf("loading posts: %w", err)
+	}
+	published := publishedPosts(allPosts)
+
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return fmt.Errorf("creating %s: %w", outDir, err)
+	}
+
+	// Render every post, including drafts: postHandler serves any slug it
+	// knows about regardless of Draft, so the static export would otherwise
+	// 404 on a page the live server happily serves.
+	for _, p := range allPosts {
+		if err := buildPostPage(outDir, p); err != nil {
+			return fmt.Errorf("building post %s: %w", p.Slug, err)
+		}
+	}
+
+	if err := buildIndexPage(outDir, published); err != nil {
+		return fmt.Errorf("building index: %w", err)
+	}
+
+	if err := buildFeeds(outDir, allPosts); err != nil {
+		return fmt.Errorf("building feeds: %w", err)
+	}
+
+	if err := buildSitemap(outDir, published); err != nil {
+		return fmt.Errorf("building sitemap: %w", err)
+	}
+
+	if err := copyStaticAssets(fsys, outDir); err != nil {
+		return fmt.Errorf("copying static assets: %w", err)
+	}
+
+	fmt.Printf("Built %d posts to %s\n", len(allPosts), outDir)
+	return nil
+}
+
+// buildPostPage renders a single post through the same "base.html"
+// template postHandler uses and writes it to <outDir>/post/<slug>/index.html.
+func buildPostPage(outDir string, p Post) error {
+	templateData := struct {
+		Title        string
+		Content      template.HTML
+		HighlightCSS template.CSS
+	}{
+		Title:        p.Title,
+		Content:      p.Content,
+		HighlightCSS: highlightCSS(),
+	}
+
+	html, err := executeTemplate(templateCache, "base.html", templateData)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(outDir, "post", p.Slug)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "index.html"), []byte(html), 0o644)
+}
+
+// buildIndexPage renders the post listing to <outDir>/index.html.
+func buildIndexPage(outDir string, published []Post) error {
+	templateData := struct {
+		Title string
+		Posts []Post
+	}{
+		Title: "Posts",
+		Posts: published,
+	}
+
+	html, err := executeTemplate(templateCache, "index.html", templateData)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(outDir, "index.html"), []byte(html), 0o644)
+}
+
+// buildFeeds writes <outDir>/feed.atom and <outDir>/feed.xml using the
+// same feed builders the /feed.atom and /feed.xml routes serve.
+func buildFeeds(outDir string, allPosts []Post) error {
+	atom, err := marshalFeed(buildAtomFeed(allPosts))
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(outDir, "feed.atom"), atom, 0o644); err != nil {
+		return err
+	}
+
+	rss, err := marshalFeed(buildRSSFeed(allPosts))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outDir, "feed.xml"), rss, 0o644)
+}
+
+// buildSitemap writes a minimal sitemap.xml covering the home page and
+// every published post.
+func buildSitemap(outDir string, published []Post) error {
+	var buf []byte
+	buf = append(buf, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"...)
+	buf = append(buf, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`+"\n"...)
+	buf = append(buf, fmt.Sprintf("  <url><loc>https://%s/</loc></url>\n", feedDomain)...)
+	for _, p := range published {
+		buf = append(buf, fmt.Sprintf("  <url><loc>%s</loc></url>\n", postPermalink(p))...)
+	}
+	buf = append(buf, "</urlset>\n"...)
+
+	return os.WriteFile(filepath.Join(outDir, "sitemap.xml"), buf, 0o644)
+}
+
+// copyStaticAssets copies any static/* files in fsys into <outDir>/static.
+// It's a no-op when fsys has no static assets.
+func copyStaticAssets(fsys fs.FS, outDir string) error {
+	entries, err := fs.ReadDir(fsys, "static")
+	if err != nil {
+		return nil
+	}
+
+	destDir := filepath.Join(outDir, "static")
+	if err := os.MkdirAll(destDir, 0o755); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, "static/"+entry.Name())
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(destDir, entry.Name()), data, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
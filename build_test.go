@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestBuildSiteWritesExpectedFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"content/hello.md": &fstest.MapFile{Data: []byte(
+			"---\ntitle: Hello\ndate: 2024-03-05\nsummary: Hi\n---\n# Hello\n",
+		)},
+		"content/draft.md": &fstest.MapFile{Data: []byte(
+			"---\ntitle: Secret\ndraft: true\n---\nShh\n",
+		)},
+		"static/site.css": &fstest.MapFile{Data: []byte("body { color: red; }")},
+	}
+
+	outDir := t.TempDir()
+	if err := buildSiteFromFS(fsys, outDir); err != nil {
+		t.Fatalf("buildSiteFromFS: %v", err)
+	}
+
+	for _, want := range []string{
+		"index.html",
+		"feed.atom",
+		"feed.xml",
+		"sitemap.xml",
+		filepath.Join("post", "hello", "index.html"),
+		// Drafts aren't listed on the index, but postHandler serves any
+		// known slug regardless of Draft, so the static export must too.
+		filepath.Join("post", "draft", "index.html"),
+		filepath.Join("static", "site.css"),
+	} {
+		if _, err := os.Stat(filepath.Join(outDir, want)); err != nil {
+			t.Errorf("expected %s to be written: %v", want, err)
+		}
+	}
+
+	// copyStaticAssets must copy from the injected fsys, not fall through
+	// to the real embedded static/ tree.
+	if _, err := os.Stat(filepath.Join(outDir, "static", "style.css")); err == nil {
+		t.Errorf("expected only fsys's static assets to be copied, not the embedded ones")
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// contentVersion is a hash over every embedded post's slug and rendered
+// content, computed once at startup. It's folded into cache keys so a
+// rebuilt binary with different embedded content never serves a stale
+// entry left over from a previous process.
+var contentVersion string
+
+// computeContentVersion hashes every post's slug and rendered content
+// into a short, stable version string.
+func computeContentVersion(all []Post) string {
+	h := sha256.New()
+	for _, p := range all {
+		fmt.Fprintf(h, "%s:%s\n", p.Slug, p.Content)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// cachedResponse is a fully rendered HTTP response body, kept in both
+// plain and gzipped form so repeat requests skip rendering entirely.
+type cachedResponse struct {
+	contentType string
+	body        []byte
+	gzipBody    []byte
+	etag        string
+}
+
+// newCachedResponse captures a rendered body as a cache entry, precomputing
+// its ETag and gzip-compressed form.
+func newCachedResponse(contentType string, body []byte) *cachedResponse {
+	sum := sha256.Sum256(body)
+
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	gw.Write(body)
+	gw.Close()
+
+	return &cachedResponse{
+		contentType: contentType,
+		body:        body,
+		gzipBody:    gz.Bytes(),
+		etag:        `"` + hex.EncodeToString(sum[:]) + `"`,
+	}
+}
+
+// serve writes the cached response, honoring If-None-Match with a 304 and
+// transparently gzipping when the client advertises support for it.
+func (c *cachedResponse) serve(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("ETag", c.etag)
+	w.Header().Set("Content-Type", c.contentType)
+
+	if r.Header.Get("If-None-Match") == c.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(c.gzipBody)
+		return
+	}
+	w.Write(c.body)
+}
+
+// responseCache is a small in-process LRU of rendered pages.
+type responseCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+type responseCacheEntry struct {
+	key      string
+	response *cachedResponse
+}
+
+func newResponseCache(capacity int) *responseCache {
+	return &responseCache{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (c *responseCache) get(key string) (*cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*responseCacheEntry).response, true
+}
+
+func (c *responseCache) set(key string, response *cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		el.Value.(*responseCacheEntry).response = response
+		c.order.MoveToFront(el)
+		return
+	}
+
+	el := c.order.PushFront(&responseCacheEntry{key: key, response: response})
+	c.items[key] = el
+
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		if oldest != nil {
+			c.order.Remove(oldest)
+			delete(c.items, oldest.Value.(*responseCacheEntry).key)
+		}
+	}
+}
+
+// pageCache holds up to 256 rendered pages and feeds, keyed by route and
+// contentVersion; once full, the least recently used entry is evicted.
+var pageCache = newResponseCache(256)
+
+// cacheKey scopes a route-level key to the current contentVersion, so
+// posts/index/feeds all get distinct, version-stamped entries.
+func cacheKey(route string) string {
+	return route + ":" + contentVersion
+}
+
+// responseRecorder buffers a handler's output so withCache can decide
+// whether to cache it before anything reaches the real ResponseWriter.
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newResponseRecorder() *responseRecorder {
+	return &responseRecorder{header: make(http.Header)}
+}
+
+func (r *responseRecorder) Header() http.Header         { return r.header }
+func (r *responseRecorder) Write(b []byte) (int, error) { return r.body.Write(b) }
+func (r *responseRecorder) WriteHeader(status int)      { r.status = status }
+
+// copyTo replays a recorded response onto a real ResponseWriter, used for
+// non-200 responses that shouldn't be cached (e.g. 404s).
+func (r *responseRecorder) copyTo(w http.ResponseWriter) {
+	for k, v := range r.header {
+		w.Header()[k] = v
+	}
+	if r.status != 0 {
+		w.WriteHeader(r.status)
+	}
+	w.Write(r.body.Bytes())
+}
+
+// withCache wraps next so that a request whose keyFunc key is not cached
+// runs next and, if it succeeds with a 200, stores its output in
+// pageCache; later requests for the same key are served straight from
+// memory with ETag and gzip support for as long as the entry stays
+// cached. Non-200 responses are passed through and never cached. It's
+// bypassed in dev mode, where content can change between requests.
+func withCache(keyFunc func(r *http.Request) string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if devMode {
+			next(w, r)
+			return
+		}
+
+		key := keyFunc(r)
+		if cached, ok := pageCache.get(key); ok {
+			cached.serve(w, r)
+			return
+		}
+
+		rec := newResponseRecorder()
+		next(rec, r)
+		if rec.status != 0 && rec.status != http.StatusOK {
+			rec.copyTo(w)
+			return
+		}
+
+		cached := newCachedResponse(rec.header.Get("Content-Type"), rec.body.Bytes())
+		pageCache.set(key, cached)
+		cached.serve(w, r)
+	}
+}
+
+// preheatCache renders the index, every published post, every tag listing
+// and both feeds up front by driving real requests through mux. Since
+// embedded content is immutable for the lifetime of the process, the
+// first real visitor to those pages never pays the template cost that
+// withCache would otherwise defer to them. Draft posts are not preheated;
+// they are rendered and cached on their first request.
+func preheatCache(mux *http.ServeMux) {
+	paths := []string{"/", "/feed.atom", "/feed.xml"}
+
+	tags := make(map[string]struct{})
+	for _, p := range publishedPosts(getPosts()) {
+		paths = append(paths, "/post/"+p.Slug)
+		for _, t := range p.Tags {
+			tags[t] = struct{}{}
+		}
+	}
+	for t := range tags {
+		paths = append(paths, "/tag/"+t)
+	}
+
+	for _, path := range paths {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		mux.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newResponseCache(2)
+
+	a := newCachedResponse("text/plain", []byte("a"))
+	b := newCachedResponse("text/plain", []byte("b"))
+	cc := newCachedResponse("text/plain", []byte("c"))
+
+	c.set("a", a)
+	c.set("b", b)
+
+	// Touch "a" so "b" becomes the least recently used entry.
+	if _, ok := c.get("a"); !ok {
+		t.Fatalf("expected %q to be cached", "a")
+	}
+
+	c.set("c", cc)
+
+	if _, ok := c.get("b"); ok {
+		t.Errorf("expected %q to have been evicted", "b")
+	}
+	if _, ok := c.get("a"); !ok {
+		t.Errorf("expected %q to still be cached", "a")
+	}
+	if _, ok := c.get("c"); !ok {
+		t.Errorf("expected %q to be cached", "c")
+	}
+}
+
+func TestCachedResponseServesNotModifiedOnMatchingETag(t *testing.T) {
+	resp := newCachedResponse("text/html; charset=utf-8", []byte("<p>hi</p>"))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-None-Match", resp.etag)
+	rec := httptest.NewRecorder()
+
+	resp.serve(rec, req)
+
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotModified)
+	}
+}
+
+func TestCachedResponseGzipsWhenAccepted(t *testing.T) {
+	resp := newCachedResponse("text/html; charset=utf-8", []byte("<p>hi</p>"))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+
+	resp.serve(rec, req)
+
+	if got := rec.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Errorf("Content-Encoding = %q, want %q", got, "gzip")
+	}
+	if rec.Body.Len() == 0 {
+		t.Errorf("expected a gzipped body to be written")
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// devMode is true when the server is reading content and views straight
+// from disk (via -dev or MORPH_DEV=1) instead of the embedded filesystem.
+var devMode bool
+
+// contentFS and viewsFS are the filesystems handlers read from. In
+// production they're both embeddedFiles; in dev mode they're the working
+// directory on disk, so views are re-parsed on every request and posts are
+// re-indexed whenever the watcher sees a change under ./content.
+var (
+	contentFS fs.FS = embeddedFiles
+	viewsFS   fs.FS = embeddedFiles
+)
+
+// liveReloadScript is injected into every rendered page in dev mode. It
+// opens an SSE connection to /dev/reload and reloads the page the moment
+// the server signals that a watched file changed.
+const liveReloadScript = `<script>
+(function() {
+	var es = new EventSource("/dev/reload");
+	es.onmessage = function() { location.reload(); };
+})();
+</script>`
+
+// isDevMode reports whether -dev was passed or MORPH_DEV=1 is set in the
+// environment.
+func isDevMode(devFlag bool) bool {
+	return devFlag || os.Getenv("MORPH_DEV") == "1"
+}
+
+// enableDevMode switches contentFS/viewsFS to real filesystem reads and
+// starts the file watcher that drives live reload.
+func enableDevMode() {
+	devMode = true
+	// loadPosts and ParseFS both expect "content/x.md" / "views/x.html"
+	// style paths, so root the dev filesystem at "." rather than at each
+	// subdirectory.
+	contentFS = os.DirFS(".")
+	viewsFS = os.DirFS(".")
+
+	go watchForChanges()
+}
+
+// currentTemplates returns the template set to render with. In production
+// it's the once-parsed templateCache; in dev mode it's re-parsed from disk
+// on every call so template edits show up without a restart.
+func currentTemplates() (*template.Template, error) {
+	if !devMode {
+		return templateCache, nil
+	}
+	return template.ParseFS(viewsFS, "views/*.html")
+}
+
+// executeTemplate renders the named template from tmpl with data and
+// returns the resulting HTML.
+func executeTemplate(tmpl *template.Template, name string, data any) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// renderTemplate executes the named template with data, injecting the
+// live-reload script in dev mode, and writes the result as HTML.
+func renderTemplate(w http.ResponseWriter, name string, data any) error {
+	tmpl, err := currentTemplates()
+	if err != nil {
+		return err
+	}
+
+	body, err := executeTemplate(tmpl, name, data)
+	if err != nil {
+		return err
+	}
+
+	if devMode {
+		body = strings.Replace(body, "</body>", liveReloadScript+"</body>", 1)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, err = fmt.Fprint(w, body)
+	return err
+}
+
+// reloadBroker fans out file-change notifications to every connected
+// /dev/reload SSE client.
+type reloadBroker struct {
+	mu      sync.Mutex
+	clients map[chan struct{}]struct{}
+}
+
+var reload = &reloadBroker{clients: make(map[chan struct{}]struct{})}
+
+func (b *reloadBroker) subscribe() chan struct{} {
+	ch := make(chan struct{}, 1)
+	b.mu.Lock()
+	b.clients[ch] = struct{}{}
+	b.mu.Unlock()
+	return ch
+}
+
+func (b *reloadBroker) unsubscribe(ch chan struct{}) {
+	b.mu.Lock()
+	delete(b.clients, ch)
+	b.mu.Unlock()
+}
+
+func (b *reloadBroker) broadcast() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.clients {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// reloadHandler serves /dev/reload, a Server-Sent Events stream that emits
+// one event every time a watched content or view file changes.
+func reloadHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ch := reload.subscribe()
+	defer reload.unsubscribe(ch)
+
+	for {
+		select {
+		case <-ch:
+			fmt.Fprint(w, "data: reload\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// watchForChanges watches ./content and ./views for writes, re-indexes
+// posts on content changes, and notifies SSE clients either way.
+func watchForChanges() {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		fmt.Printf("dev mode: could not start file watcher: %v\n", err)
+		return
+	}
+	defer watcher.Close()
+
+	for _, dir := range []string{"content", "views"} {
+		if err := watcher.Add(dir); err != nil {
+			fmt.Printf("dev mode: could not watch %s: %v\n", dir, err)
+		}
+	}
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) == 0 {
+				continue
+			}
+			if strings.HasPrefix(event.Name, "content") {
+				if reloaded, err := loadPosts(contentFS); err == nil {
+					setPosts(reloaded)
+				} else {
+					fmt.Printf("dev mode: failed to reload posts: %v\n", err)
+				}
+			}
+			reload.broadcast()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			fmt.Printf("dev mode: watcher error: %v\n", err)
+		}
+	}
+}
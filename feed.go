@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// envOrDefault returns the value of the named environment variable, or
+// fallback if it's unset or empty. It's used to seed flag defaults so
+// -domain/-start-date and MORPH_DOMAIN/MORPH_START_DATE both work, with
+// the flag taking precedence when both are given.
+func envOrDefault(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// feedDomain is the canonical domain the blog is served from, used to
+// build permalinks and Atom tag: URIs. Configurable via -domain or
+// MORPH_DOMAIN; defaults to example.com for local development.
+var feedDomain = "example.com"
+
+// feedStartDate anchors the "year" component of Atom tag: URIs, per the
+// tag: URI scheme (RFC 4151): it must be a date on which the author owned
+// feedDomain. Configurable via -start-date or MORPH_START_DATE. Must be
+// validated with validateFeedStartDate before use.
+var feedStartDate = "2024-01-01"
+
+// validateFeedStartDate checks that s is a well-formed YYYY-MM-DD date,
+// so callers can fail fast on a bad -start-date/MORPH_START_DATE instead
+// of panicking the first time a feed is rendered.
+func validateFeedStartDate(s string) error {
+	if _, err := time.Parse(frontMatterDateLayout, s); err != nil {
+		return fmt.Errorf("invalid start date %q (want YYYY-MM-DD): %w", s, err)
+	}
+	return nil
+}
+
+// feedStartYear returns the year component of feedStartDate for use in
+// tag: URIs. feedStartDate is expected to have passed
+// validateFeedStartDate; if it doesn't parse, time.Parse yields the zero
+// time and the year "0001" is returned, never an empty string.
+func feedStartYear() string {
+	t, _ := time.Parse(frontMatterDateLayout, feedStartDate)
+	return t.Format("2006")
+}
+
+// atomFeed is the root element of an Atom 1.0 feed.
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Link    []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title     string      `xml:"title"`
+	ID        string      `xml:"id"`
+	Link      atomLink    `xml:"link"`
+	Published string      `xml:"published"`
+	Updated   string      `xml:"updated"`
+	Summary   string      `xml:"summary"`
+	Content   atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",cdata"`
+}
+
+// rssFeed is the root element of an RSS 2.0 feed.
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title string    `xml:"title"`
+	Link  string    `xml:"link"`
+	Desc  string    `xml:"description"`
+	Items []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title   string `xml:"title"`
+	Link    string `xml:"link"`
+	GUID    string `xml:"guid"`
+	PubDate string `xml:"pubDate"`
+	Desc    string `xml:"description"`
+}
+
+// postPermalink returns the canonical, absolute URL for a post.
+func postPermalink(p Post) string {
+	return fmt.Sprintf("https://%s/post/%s", feedDomain, p.Slug)
+}
+
+// postTagURI builds a tag: URI (RFC 4151) for a post, anchored to
+// feedStartDate, suitable for use as a stable Atom entry id.
+func postTagURI(p Post) string {
+	return fmt.Sprintf("tag:%s,%s:/post/%s", feedDomain, feedStartYear(), p.Slug)
+}
+
+// buildAtomFeed assembles an Atom 1.0 feed from published posts, sorted
+// newest first.
+func buildAtomFeed(all []Post) atomFeed {
+	published := publishedPosts(all)
+
+	updated := feedStartDate
+	if len(published) > 0 {
+		updated = published[0].Date.Format(time.RFC3339)
+	}
+
+	feed := atomFeed{
+		Xmlns:   "http://www.w3.org/2005/Atom",
+		Title:   "morph-blog",
+		ID:      fmt.Sprintf("tag:%s,%s:/", feedDomain, feedStartYear()),
+		Updated: updated,
+		Link: []atomLink{
+			{Href: fmt.Sprintf("https://%s/", feedDomain)},
+			{Href: fmt.Sprintf("https://%s/feed.atom", feedDomain), Rel: "self"},
+		},
+	}
+
+	for _, p := range published {
+		feed.Entries = append(feed.Entries, atomEntry{
+			Title:     p.Title,
+			ID:        postTagURI(p),
+			Link:      atomLink{Href: postPermalink(p)},
+			Published: p.Date.Format(time.RFC3339),
+			Updated:   p.Date.Format(time.RFC3339),
+			Summary:   p.Summary,
+			Content:   atomContent{Type: "html", Body: string(p.Content)},
+		})
+	}
+
+	return feed
+}
+
+// buildRSSFeed assembles an RSS 2.0 feed from published posts, sorted
+// newest first.
+func buildRSSFeed(all []Post) rssFeed {
+	published := publishedPosts(all)
+
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title: "morph-blog",
+			Link:  fmt.Sprintf("https://%s/", feedDomain),
+			Desc:  "Latest posts from morph-blog",
+		},
+	}
+
+	for _, p := range published {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:   p.Title,
+			Link:    postPermalink(p),
+			GUID:    postPermalink(p),
+			PubDate: p.Date.Format(time.RFC1123Z),
+			Desc:    p.Summary,
+		})
+	}
+
+	return feed
+}
+
+// marshalFeed renders a feed value (atomFeed or rssFeed) as an
+// XML-declaration-prefixed document.
+func marshalFeed(body any) ([]byte, error) {
+	encoded, err := xml.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), encoded...), nil
+}
+
+// feedHandler serves /feed.atom and /feed.xml, rendering the same post
+// index as an Atom or RSS feed depending on the requested path.
+func feedHandler(w http.ResponseWriter, r *http.Request) {
+	var (
+		body        any
+		contentType string
+	)
+
+	all := getPosts()
+	switch r.URL.Path {
+	case "/feed.atom":
+		body = buildAtomFeed(all)
+		contentType = "application/atom+xml; charset=utf-8"
+	case "/feed.xml":
+		body = buildRSSFeed(all)
+		contentType = "application/rss+xml; charset=utf-8"
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	out, err := marshalFeed(body)
+	if err != nil {
+		http.Error(w, "Feed rendering error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(out)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFeedURLsUseConfiguredDomainAndStartDate(t *testing.T) {
+	origDomain, origStart := feedDomain, feedStartDate
+	t.Cleanup(func() {
+		feedDomain, feedStartDate = origDomain, origStart
+	})
+
+	feedDomain = "blog.example.org"
+	feedStartDate = "2020-06-15"
+
+	p := Post{Slug: "hello", Title: "Hello", Date: time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)}
+
+	if got, want := postPermalink(p), "https://blog.example.org/post/hello"; got != want {
+		t.Errorf("postPermalink = %q, want %q", got, want)
+	}
+
+	if tagURI := postTagURI(p); !strings.HasPrefix(tagURI, "tag:blog.example.org,2020:") {
+		t.Errorf("postTagURI = %q, want prefix %q", tagURI, "tag:blog.example.org,2020:")
+	}
+
+	feed := buildAtomFeed([]Post{p})
+	if len(feed.Entries) != 1 || feed.Entries[0].Link.Href != "https://blog.example.org/post/hello" {
+		t.Errorf("atom entry link = %+v, want domain blog.example.org", feed.Entries)
+	}
+}
+
+func TestValidateFeedStartDateRejectsMalformedInput(t *testing.T) {
+	for _, bad := range []string{"", "2024", "not-a-date", "2024/01/01"} {
+		if err := validateFeedStartDate(bad); err == nil {
+			t.Errorf("validateFeedStartDate(%q) = nil, want an error", bad)
+		}
+	}
+
+	if err := validateFeedStartDate("2024-01-01"); err != nil {
+		t.Errorf("validateFeedStartDate(%q) = %v, want nil", "2024-01-01", err)
+	}
+}
+
+func TestFeedStartYearDoesNotPanicOnEmptyStartDate(t *testing.T) {
+	origStart := feedStartDate
+	t.Cleanup(func() { feedStartDate = origStart })
+
+	feedStartDate = ""
+	if year := feedStartYear(); year == "" {
+		t.Errorf("feedStartYear() = %q, want a non-panicking fallback", year)
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// withPosts installs p as the post index for the duration of the test and
+// restores whatever was there before.
+func withPosts(t *testing.T, p []Post) {
+	t.Helper()
+	orig := getPosts()
+	setPosts(p)
+	t.Cleanup(func() { setPosts(orig) })
+}
+
+func TestPostHandlerServesDraftsBySlug(t *testing.T) {
+	withPosts(t, []Post{{Slug: "secret", Title: "Secret", Draft: true, Content: "<p>shh</p>"}})
+
+	req := httptest.NewRequest(http.MethodGet, "/post/secret", nil)
+	rec := httptest.NewRecorder()
+	postHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if !strings.Contains(rec.Body.String(), "shh") {
+		t.Errorf("body = %q, want draft content present", rec.Body.String())
+	}
+}
+
+func TestPostHandlerUnknownSlugReturns404(t *testing.T) {
+	withPosts(t, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/post/nope", nil)
+	rec := httptest.NewRecorder()
+	postHandler(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestIndexHandlerListsOnlyPublishedPosts(t *testing.T) {
+	withPosts(t, []Post{
+		{Slug: "live", Title: "Live"},
+		{Slug: "draft", Title: "Draft", Draft: true},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	indexHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "/post/live") {
+		t.Errorf("body missing published post link: %q", body)
+	}
+	if strings.Contains(body, "/post/draft") {
+		t.Errorf("body lists draft post, want it omitted: %q", body)
+	}
+}
+
+func TestIndexHandlerOnlyMatchesRoot(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/nope", nil)
+	rec := httptest.NewRecorder()
+	indexHandler(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestTagHandlerFiltersByTag(t *testing.T) {
+	withPosts(t, []Post{
+		{Slug: "go-post", Title: "Go Post", Tags: []string{"go"}},
+		{Slug: "other-post", Title: "Other Post", Tags: []string{"other"}},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/tag/go", nil)
+	rec := httptest.NewRecorder()
+	tagHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "/post/go-post") {
+		t.Errorf("body missing tagged post link: %q", body)
+	}
+	if strings.Contains(body, "/post/other-post") {
+		t.Errorf("body lists post without the tag: %q", body)
+	}
+}
+
+func TestTagHandlerEmptyTagReturns404(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/tag/", nil)
+	rec := httptest.NewRecorder()
+	tagHandler(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestFeedHandlerServesAtomAndRSS(t *testing.T) {
+	withPosts(t, []Post{{Slug: "hello", Title: "Hello"}})
+
+	for path, contentType := range map[string]string{
+		"/feed.atom": "application/atom+xml",
+		"/feed.xml":  "application/rss+xml",
+	} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rec := httptest.NewRecorder()
+		feedHandler(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, want %d", path, rec.Code, http.StatusOK)
+		}
+		if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, contentType) {
+			t.Errorf("%s: Content-Type = %q, want prefix %q", path, got, contentType)
+		}
+	}
+}
+
+func TestFeedHandlerUnknownPathReturns404(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/feed.unknown", nil)
+	rec := httptest.NewRecorder()
+	feedHandler(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestWithCacheServesNotModifiedAndGzip(t *testing.T) {
+	origVersion := contentVersion
+	contentVersion = "handlers-test-" + t.Name()
+	t.Cleanup(func() { contentVersion = origVersion })
+
+	calls := 0
+	wrapped := withCache(func(r *http.Request) string {
+		return cacheKey("widget")
+	}, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		io.WriteString(w, "hello cache")
+	})
+
+	first := httptest.NewRecorder()
+	wrapped(first, httptest.NewRequest(http.MethodGet, "/widget", nil))
+	if first.Code != http.StatusOK || first.Body.String() != "hello cache" {
+		t.Fatalf("first request: status=%d body=%q", first.Code, first.Body.String())
+	}
+
+	second := httptest.NewRecorder()
+	wrapped(second, httptest.NewRequest(http.MethodGet, "/widget", nil))
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1 (second request should hit the cache)", calls)
+	}
+	etag := second.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("ETag header missing from cached response")
+	}
+
+	notModified := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/widget", nil)
+	req.Header.Set("If-None-Match", etag)
+	wrapped(notModified, req)
+	if notModified.Code != http.StatusNotModified {
+		t.Errorf("status = %d, want %d", notModified.Code, http.StatusNotModified)
+	}
+
+	gzipped := httptest.NewRecorder()
+	gzReq := httptest.NewRequest(http.MethodGet, "/widget", nil)
+	gzReq.Header.Set("Accept-Encoding", "gzip")
+	wrapped(gzipped, gzReq)
+	if gzipped.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", gzipped.Header().Get("Content-Encoding"))
+	}
+	gr, err := gzip.NewReader(gzipped.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	decoded, err := io.ReadAll(gr)
+	if err != nil {
+		t.Fatalf("reading gzip body: %v", err)
+	}
+	if string(decoded) != "hello cache" {
+		t.Errorf("decoded gzip body = %q, want %q", decoded, "hello cache")
+	}
+}
+
+func TestRenderTemplateInjectsLiveReloadScriptInDevMode(t *testing.T) {
+	origDevMode := devMode
+	t.Cleanup(func() { devMode = origDevMode })
+
+	devMode = true
+	rec := httptest.NewRecorder()
+	data := struct {
+		Title string
+		Posts []Post
+	}{Title: "Posts"}
+
+	if err := renderTemplate(rec, "index.html", data); err != nil {
+		t.Fatalf("renderTemplate: %v", err)
+	}
+	if !strings.Contains(rec.Body.String(), "/dev/reload") {
+		t.Errorf("body missing live-reload script in dev mode: %q", rec.Body.String())
+	}
+
+	devMode = false
+	rec = httptest.NewRecorder()
+	if err := renderTemplate(rec, "index.html", data); err != nil {
+		t.Fatalf("renderTemplate: %v", err)
+	}
+	if strings.Contains(rec.Body.String(), "/dev/reload") {
+		t.Errorf("body has live-reload script outside dev mode: %q", rec.Body.String())
+	}
+}
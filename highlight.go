@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/gomarkdown/markdown/ast"
+)
+
+// chromaStyleName is the Chroma style used to highlight fenced code
+// blocks, configurable via the -highlight-style flag.
+var chromaStyleName = "github"
+
+// highlightFormatter renders tokenised code as classed HTML spans rather
+// than inline styles, so the matching CSS can be emitted once per page
+// via highlightCSS instead of repeated on every block.
+var highlightFormatter = chromahtml.New(chromahtml.WithClasses(true), chromahtml.ClassPrefix("chroma-"))
+
+// chromaRenderHook is a gomarkdown RenderNodeFunc that intercepts
+// CodeBlock nodes and highlights them with Chroma instead of letting the
+// default renderer emit a bare <pre><code> block.
+func chromaRenderHook(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool) {
+	codeBlock, ok := node.(*ast.CodeBlock)
+	if !ok {
+		return ast.GoToNext, false
+	}
+	highlightCodeBlock(w, codeBlock)
+	return ast.GoToNext, true
+}
+
+// highlightCodeBlock writes a Chroma-highlighted rendering of a fenced
+// code block, detecting the language from its info string (falling back
+// to content analysis, then plain text), and falls back to an
+// unhighlighted <pre><code> block if tokenising fails.
+func highlightCodeBlock(w io.Writer, node *ast.CodeBlock) {
+	lang := strings.TrimSpace(string(node.Info))
+	lexer := lexers.Get(lang)
+	if lexer == nil {
+		lexer = lexers.Analyse(string(node.Literal))
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	lexer = chroma.Coalesce(lexer)
+
+	iterator, err := lexer.Tokenise(nil, string(node.Literal))
+	if err != nil {
+		fmt.Fprintf(w, "<pre><code>%s</code></pre>", template.HTMLEscapeString(string(node.Literal)))
+		return
+	}
+
+	if err := highlightFormatter.Format(w, highlightStyle(), iterator); err != nil {
+		fmt.Fprintf(w, "<pre><code>%s</code></pre>", template.HTMLEscapeString(string(node.Literal)))
+	}
+}
+
+// highlightStyle resolves the configured Chroma style, falling back to
+// the package default if the name is unknown.
+func highlightStyle() *chroma.Style {
+	if style := styles.Get(chromaStyleName); style != nil {
+		return style
+	}
+	return styles.Fallback
+}
+
+// highlightCSS returns the CSS rules for the configured Chroma style,
+// without a surrounding <style> element; the template renders them once
+// per page inside one, e.g.
+// <style>{{.HighlightCSS}}</style> in base.html's <head>.
+func highlightCSS() template.CSS {
+	var buf strings.Builder
+	if err := highlightFormatter.WriteCSS(&buf, highlightStyle()); err != nil {
+		return ""
+	}
+	return template.CSS(buf.String())
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+func TestHighlightCodeBlockEmitsChromaMarkupForKnownLanguage(t *testing.T) {
+	var buf bytes.Buffer
+	node := &ast.CodeBlock{Info: []byte("go"), Literal: []byte("func main() {}\n")}
+
+	highlightCodeBlock(&buf, node)
+
+	if !strings.Contains(buf.String(), "chroma-") {
+		t.Errorf("highlightCodeBlock output = %q, want Chroma-classed markup", buf.String())
+	}
+}
+
+func TestHighlightCodeBlockFallsBackOnUnknownLanguage(t *testing.T) {
+	var buf bytes.Buffer
+	node := &ast.CodeBlock{Info: []byte("not-a-real-language"), Literal: []byte("whatever\n")}
+
+	highlightCodeBlock(&buf, node)
+
+	if !strings.Contains(buf.String(), "whatever") {
+		t.Errorf("highlightCodeBlock output = %q, want the literal content preserved", buf.String())
+	}
+}
+
+func TestHighlightCSSChangesWithStyle(t *testing.T) {
+	origStyle := chromaStyleName
+	t.Cleanup(func() { chromaStyleName = origStyle })
+
+	chromaStyleName = "github"
+	github := highlightCSS()
+
+	chromaStyleName = "monokai"
+	monokai := highlightCSS()
+
+	if github == "" || monokai == "" {
+		t.Fatal("highlightCSS() returned empty CSS for a known style")
+	}
+	if github == monokai {
+		t.Errorf("highlightCSS() did not change between \"github\" and \"monokai\" styles")
+	}
+}
+
+func TestHighlightStyleFallsBackOnUnknownName(t *testing.T) {
+	origStyle := chromaStyleName
+	t.Cleanup(func() { chromaStyleName = origStyle })
+
+	chromaStyleName = "not-a-real-style"
+	if style := highlightStyle(); style == nil {
+		t.Error("highlightStyle() = nil, want a fallback style")
+	}
+}
@@ -2,9 +2,12 @@ package main
 
 import (
 	"embed"
+	"flag"
 	"fmt"
 	"html/template"
+	"io/fs"
 	"net/http"
+	"os"
 	"strings"
 
 	"github.com/gomarkdown/markdown"
@@ -16,6 +19,7 @@ import (
 //
 //go:embed content/*.md
 //go:embed views/*.html
+//go:embed static/*
 var embeddedFiles embed.FS
 
 // TemplateCache holds the parsed templates
@@ -27,9 +31,10 @@ func mdToHTML(md []byte) []byte {
 	p := parser.NewWithExtensions(extensions)
 	doc := p.Parse(md)
 
-	// create HTML renderer with extensions
+	// create HTML renderer with extensions, routing fenced code blocks
+	// through the Chroma syntax highlighter
 	htmlFlags := html.CommonFlags | html.HrefTargetBlank
-	opts := html.RendererOptions{Flags: htmlFlags}
+	opts := html.RendererOptions{Flags: htmlFlags, RenderNodeHook: chromaRenderHook}
 	renderer := html.NewRenderer(opts)
 
 	return markdown.Render(doc, renderer)
@@ -45,57 +50,170 @@ func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // postHandler is the handler function for the /post/:slug route.
-// It reads a TXT file based on the slug and returns its content.
+// It looks up the post by slug in the in-memory index and renders it.
 func postHandler(w http.ResponseWriter, r *http.Request) {
 	// Extract the slug from the URL path
 	slug := strings.TrimPrefix(r.URL.Path, "/post/")
 	slug = strings.TrimSuffix(slug, "/")
 
-	// Construct the file path based on the slug
-	filePath := fmt.Sprintf("content/%s.md", slug)
-
-	// Read the file from the embedded content
-	data, err := embeddedFiles.ReadFile(filePath)
-	if err != nil {
+	post, ok := postBySlug(slug)
+	if !ok {
 		http.Error(w, "File not found", http.StatusNotFound)
 		return
 	}
 
-	html := template.HTML(string(mdToHTML(data)))
-
 	// Prepare data to pass to the template
 	templateData := struct {
-		Title   string
-		Content template.HTML
+		Title        string
+		Content      template.HTML
+		HighlightCSS template.CSS
 	}{
-		Title:   slug,
-		Content: html,
+		Title:        post.Title,
+		Content:      post.Content,
+		HighlightCSS: highlightCSS(),
 	}
 
-	// Set the content type to plain text and write the file content
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	// Render the template with the data
-	err = templateCache.ExecuteTemplate(w, "base.html", templateData)
-	if err != nil {
+	if err := renderTemplate(w, "base.html", templateData); err != nil {
+		http.Error(w, "Template rendering error", http.StatusInternalServerError)
+	}
+}
+
+// indexHandler is the handler function for the / route. It renders a
+// chronological listing of every published post.
+func indexHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	templateData := struct {
+		Title string
+		Posts []Post
+	}{
+		Title: "Posts",
+		Posts: publishedPosts(getPosts()),
+	}
+
+	if err := renderTemplate(w, "index.html", templateData); err != nil {
+		http.Error(w, "Template rendering error", http.StatusInternalServerError)
+	}
+}
+
+// tagHandler is the handler function for the /tag/:tag route. It renders
+// the same listing template, filtered down to posts carrying that tag.
+func tagHandler(w http.ResponseWriter, r *http.Request) {
+	tag := strings.TrimPrefix(r.URL.Path, "/tag/")
+	tag = strings.TrimSuffix(tag, "/")
+	if tag == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	templateData := struct {
+		Title string
+		Posts []Post
+	}{
+		Title: fmt.Sprintf("Posts tagged %q", tag),
+		Posts: postsWithTag(getPosts(), tag),
+	}
+
+	if err := renderTemplate(w, "index.html", templateData); err != nil {
 		http.Error(w, "Template rendering error", http.StatusInternalServerError)
 	}
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "build" {
+		if err := runBuildCmd(os.Args[2:]); err != nil {
+			fmt.Printf("Error building site: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	devFlag := flag.Bool("dev", false, "read content and views from disk and live-reload on change")
+	styleFlag := flag.String("highlight-style", chromaStyleName, "Chroma style used for syntax highlighting")
+	domainFlag := flag.String("domain", envOrDefault("MORPH_DOMAIN", feedDomain), "domain the blog is served from (also MORPH_DOMAIN)")
+	startDateFlag := flag.String("start-date", envOrDefault("MORPH_START_DATE", feedStartDate), "date (YYYY-MM-DD) the domain was first owned, anchoring feed tag: URIs (also MORPH_START_DATE)")
+	flag.Parse()
+	chromaStyleName = *styleFlag
+	feedDomain = *domainFlag
+	feedStartDate = *startDateFlag
+	if err := validateFeedStartDate(feedStartDate); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if isDevMode(*devFlag) {
+		enableDevMode()
+		fmt.Println("Dev mode enabled: serving content and views from disk")
+	}
+
+	// Parse front matter and Markdown for every embedded post up front.
+	loadedPosts, err := loadPosts(contentFS)
+	if err != nil {
+		fmt.Printf("Error loading posts: %v\n", err)
+		return
+	}
+	setPosts(loadedPosts)
+	contentVersion = computeContentVersion(loadedPosts)
+
 	// Create a new ServeMux to handle routes
 	mux := http.NewServeMux()
 
 	// Register the /health route with the healthCheckHandler
 	mux.HandleFunc("/health", healthCheckHandler)
 
-	// Register the /post/:slug route with the postHandler
-	mux.HandleFunc("/post/", postHandler)
+	// Register /static/ to serve embedded assets (or, in dev mode, the
+	// real ./static directory)
+	staticFS, err := fs.Sub(contentFS, "static")
+	if err != nil {
+		fmt.Printf("Error mounting static assets: %v\n", err)
+		return
+	}
+	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServerFS(staticFS)))
+
+	// Register the / route with the indexHandler, cached per path; any
+	// path other than "/" is a 404 and so is never cached
+	mux.HandleFunc("/", withCache(func(r *http.Request) string {
+		return cacheKey("index:" + r.URL.Path)
+	}, indexHandler))
+
+	// Register the /post/:slug route with the postHandler, cached per slug
+	mux.HandleFunc("/post/", withCache(func(r *http.Request) string {
+		slug := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/post/"), "/")
+		return cacheKey("post:" + slug)
+	}, postHandler))
+
+	// Register the /tag/:tag route with the tagHandler, cached per tag
+	mux.HandleFunc("/tag/", withCache(func(r *http.Request) string {
+		tag := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/tag/"), "/")
+		return cacheKey("tag:" + tag)
+	}, tagHandler))
+
+	// Register the /feed.atom and /feed.xml routes with the feedHandler
+	mux.HandleFunc("/feed.atom", withCache(func(r *http.Request) string {
+		return cacheKey("feed.atom")
+	}, feedHandler))
+	mux.HandleFunc("/feed.xml", withCache(func(r *http.Request) string {
+		return cacheKey("feed.xml")
+	}, feedHandler))
+
+	if devMode {
+		// Register the /dev/reload route with the reloadHandler
+		mux.HandleFunc("/dev/reload", reloadHandler)
+	} else {
+		// Content is embedded and immutable for this process, so warm
+		// every published page and feed before the first real request
+		// arrives.
+		preheatCache(mux)
+	}
 
 	// Start the HTTP server on port 8080
 	// The ListenAndServe function takes an address and a handler.
 	// Here, we use ":8080" for the address and our ServeMux for the handler.
 	fmt.Println("Starting server on :8080...")
-	err := http.ListenAndServe(":8080", mux)
+	err = http.ListenAndServe(":8080", mux)
 	if err != nil {
 		// Log any errors that occur while starting the server
 		fmt.Printf("Error starting server: %v\n", err)
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"io/fs"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adrg/frontmatter"
+)
+
+// Post represents a single blog post, combining metadata parsed from the
+// file's front matter with its slug and rendered HTML body.
+type Post struct {
+	Slug    string
+	Title   string
+	Date    time.Time
+	Tags    []string
+	Summary string
+	Draft   bool
+	Content template.HTML
+}
+
+// postMatter mirrors the front matter fields we expect to find at the top
+// of each content/*.md file, in either YAML or TOML form.
+type postMatter struct {
+	Title   string   `yaml:"title" toml:"title"`
+	Date    string   `yaml:"date" toml:"date"`
+	Tags    []string `yaml:"tags" toml:"tags"`
+	Summary string   `yaml:"summary" toml:"summary"`
+	Draft   bool     `yaml:"draft" toml:"draft"`
+}
+
+// frontMatterDateLayout is the expected format for the "date" front matter
+// field, e.g. "2024-03-05".
+const frontMatterDateLayout = "2006-01-02"
+
+// posts is the in-memory index of every post, drafts included, sorted
+// newest first. It's populated at startup by loadPosts and, in dev mode,
+// replaced wholesale on every content change by watchForChanges, so all
+// access goes through postsMu rather than touching the slice directly.
+var (
+	postsMu sync.RWMutex
+	posts   []Post
+)
+
+// getPosts returns the current post index.
+func getPosts() []Post {
+	postsMu.RLock()
+	defer postsMu.RUnlock()
+	return posts
+}
+
+// setPosts replaces the post index.
+func setPosts(p []Post) {
+	postsMu.Lock()
+	posts = p
+	postsMu.Unlock()
+}
+
+// loadPosts walks content/*.md in fsys, parses each file's front matter and
+// Markdown body, and returns the resulting posts sorted newest first.
+func loadPosts(fsys fs.FS) ([]Post, error) {
+	entries, err := fs.ReadDir(fsys, "content")
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Post
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+			continue
+		}
+
+		slug := strings.TrimSuffix(entry.Name(), ".md")
+
+		data, err := fs.ReadFile(fsys, "content/"+entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		var matter postMatter
+		body, err := frontmatter.Parse(bytes.NewReader(data), &matter)
+		if err != nil {
+			return nil, err
+		}
+
+		post := Post{
+			Slug:    slug,
+			Title:   matter.Title,
+			Tags:    matter.Tags,
+			Summary: matter.Summary,
+			Draft:   matter.Draft,
+			Content: template.HTML(mdToHTML(body)),
+		}
+		if post.Title == "" {
+			post.Title = slug
+		}
+		if matter.Date != "" {
+			post.Date, err = time.Parse(frontMatterDateLayout, matter.Date)
+			if err != nil {
+				log.Printf("post %s: invalid date %q: %v", slug, matter.Date, err)
+			}
+		}
+
+		result = append(result, post)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Date.After(result[j].Date)
+	})
+
+	return result, nil
+}
+
+// postBySlug returns the post with the given slug, if it has been indexed.
+func postBySlug(slug string) (Post, bool) {
+	for _, p := range getPosts() {
+		if p.Slug == slug {
+			return p, true
+		}
+	}
+	return Post{}, false
+}
+
+// publishedPosts returns posts that aren't marked as drafts.
+func publishedPosts(all []Post) []Post {
+	var result []Post
+	for _, p := range all {
+		if !p.Draft {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// postsWithTag returns published posts that carry the given tag.
+func postsWithTag(all []Post, tag string) []Post {
+	var result []Post
+	for _, p := range all {
+		if p.Draft {
+			continue
+		}
+		for _, t := range p.Tags {
+			if t == tag {
+				result = append(result, p)
+				break
+			}
+		}
+	}
+	return result
+}
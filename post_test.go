@@ -0,0 +1,96 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadPostsParsesFrontMatter(t *testing.T) {
+	fsys := fstest.MapFS{
+		"content/hello.md": &fstest.MapFile{Data: []byte(strings.Join([]string{
+			"---",
+			`title: Hello World`,
+			"date: 2024-03-05",
+			`tags: ["go", "blog"]`,
+			"summary: A first post",
+			"draft: false",
+			"---",
+			"# Hello",
+			"",
+			"Body text.",
+			"",
+		}, "\n"))},
+	}
+
+	posts, err := loadPosts(fsys)
+	if err != nil {
+		t.Fatalf("loadPosts: %v", err)
+	}
+	if len(posts) != 1 {
+		t.Fatalf("len(posts) = %d, want 1", len(posts))
+	}
+
+	p := posts[0]
+	if p.Slug != "hello" {
+		t.Errorf("Slug = %q, want %q", p.Slug, "hello")
+	}
+	if p.Title != "Hello World" {
+		t.Errorf("Title = %q, want %q", p.Title, "Hello World")
+	}
+	if p.Date.Format(frontMatterDateLayout) != "2024-03-05" {
+		t.Errorf("Date = %v, want 2024-03-05", p.Date)
+	}
+	if len(p.Tags) != 2 || p.Tags[0] != "go" || p.Tags[1] != "blog" {
+		t.Errorf("Tags = %v, want [go blog]", p.Tags)
+	}
+	if !strings.Contains(string(p.Content), "<h1") {
+		t.Errorf("Content = %q, want rendered Markdown", p.Content)
+	}
+}
+
+func TestLoadPostsFallsBackToSlugTitle(t *testing.T) {
+	fsys := fstest.MapFS{
+		"content/untitled.md": &fstest.MapFile{Data: []byte("no front matter here\n")},
+	}
+
+	posts, err := loadPosts(fsys)
+	if err != nil {
+		t.Fatalf("loadPosts: %v", err)
+	}
+	if len(posts) != 1 || posts[0].Title != "untitled" {
+		t.Fatalf("posts = %+v, want a single post titled %q", posts, "untitled")
+	}
+}
+
+func TestPublishedPostsExcludesDrafts(t *testing.T) {
+	fsys := fstest.MapFS{
+		"content/draft.md": &fstest.MapFile{Data: []byte("---\ntitle: Draft\ndraft: true\n---\nBody\n")},
+		"content/live.md":  &fstest.MapFile{Data: []byte("---\ntitle: Live\ndraft: false\n---\nBody\n")},
+	}
+
+	all, err := loadPosts(fsys)
+	if err != nil {
+		t.Fatalf("loadPosts: %v", err)
+	}
+
+	published := publishedPosts(all)
+	if len(published) != 1 || published[0].Slug != "live" {
+		t.Errorf("publishedPosts = %+v, want only %q", published, "live")
+	}
+}
+
+func TestPostsAccessorsAreConcurrencySafe(t *testing.T) {
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 100; i++ {
+			setPosts([]Post{{Slug: "a"}})
+		}
+		close(done)
+	}()
+
+	for i := 0; i < 100; i++ {
+		_ = getPosts()
+	}
+	<-done
+}